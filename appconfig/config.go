@@ -0,0 +1,84 @@
+// Package appconfig holds the process-wide configuration loaded at
+// startup and shared by the DNS server, the HTTP admin API, and the
+// monitoring subsystems.
+package appconfig
+
+// Config is the global, process-wide configuration instance, populated
+// during startup from the on-disk config file.
+var Config Configuration
+
+// Configuration is the top-level configuration document.
+type Configuration struct {
+	HTTP HTTPConfig `toml:"http"`
+}
+
+// HTTPConfig configures the HTTP admin API in http.NewHTTPServer.
+type HTTPConfig struct {
+	// Token is the legacy shared bearer token. It is no longer checked:
+	// the HTTP admin API accepts only JWT bearer tokens (see JWT).
+	Token string `toml:"token"`
+
+	JWT      JWTConfig      `toml:"jwt"`
+	CORS     CORSConfig     `toml:"cors"`
+	Security SecurityConfig `toml:"security"`
+	DoH      DoHConfig      `toml:"doh"`
+}
+
+// DoHConfig configures the RFC 8484 DNS-over-HTTPS endpoint.
+type DoHConfig struct {
+	// TrustedProxies lists the IPs allowed to supply an X-Forwarded-For
+	// header that the DoH handler will trust for synthesizing EDNS
+	// Client Subnet.
+	TrustedProxies []string `toml:"trusted_proxies"`
+}
+
+// CORSConfig configures the browser CORS policy for the HTTP admin API.
+type CORSConfig struct {
+	// AllowedOrigins lists origins allowed to call the API, or ["*"] for any.
+	AllowedOrigins []string `toml:"allowed_origins"`
+
+	// AllowedMethods overrides the default allow-list sent on preflight responses.
+	AllowedMethods []string `toml:"allowed_methods"`
+
+	// AllowedHeaders overrides the default allow-list sent on preflight responses.
+	AllowedHeaders []string `toml:"allowed_headers"`
+}
+
+// SecurityConfig configures the security-header middleware for the HTTP admin API.
+type SecurityConfig struct {
+	// EnforceHTTPS sets Strict-Transport-Security and redirects requests
+	// arriving over plain HTTP (as seen via X-Forwarded-Proto) to HTTPS.
+	EnforceHTTPS bool `toml:"enforce_https"`
+
+	// STSMaxAge is the Strict-Transport-Security max-age, in seconds.
+	// Defaults to one year when zero and EnforceHTTPS is set.
+	STSMaxAge int `toml:"sts_max_age"`
+
+	// ContentSecurityPolicy overrides the default restrictive CSP.
+	ContentSecurityPolicy string `toml:"content_security_policy"`
+}
+
+// JWTConfig configures JWT verification for the HTTP admin API.
+type JWTConfig struct {
+	// Issuer, when set, is required to match the "iss" claim on every token.
+	Issuer string `toml:"issuer"`
+
+	// Keys maps a token's "kid" header to the key used to verify it.
+	Keys map[string]JWTKey `toml:"keys"`
+}
+
+// JWTKey is a single signing key, identified by its "kid". Algorithm
+// pins the key to exactly one JWT signing method: a token's "alg"
+// header is untrusted input and must never be allowed to select which
+// field (Secret vs PublicKey) is used to verify it.
+type JWTKey struct {
+	// Algorithm is the only signing method this key may verify with,
+	// e.g. "HS256" or "RS256".
+	Algorithm string `toml:"algorithm"`
+
+	// Secret is the HMAC secret, set when Algorithm is "HS256".
+	Secret string `toml:"secret"`
+
+	// PublicKey is the PEM-encoded RSA public key, set when Algorithm is "RS256".
+	PublicKey string `toml:"public_key"`
+}
@@ -0,0 +1,282 @@
+package http
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abh/geodns/v3/zones"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	archiveMaxFileSize    = 8 << 20   // 8MB per zone file, generous for hand-edited JSON
+	archiveMaxTotalSize   = 512 << 20 // cap on cumulative decompressed size per import
+	archiveMaxEntries     = 10000     // cap on archive entries per import
+	archiveMaxRequestBody = 64 << 20  // cap on the compressed request body itself
+)
+
+// exportZones streams every zone JSON file under hs.zonePath as a single
+// .tar.gz, so an operator can move hundreds of zones between nodes
+// without looping over GET /zone/:zone. Every file is read up front so a
+// read error aborts the whole export with a JSON error instead of
+// silently truncating the archive after headers are already sent.
+func (hs *httpServer) exportZones(c *gin.Context) {
+	entries, err := os.ReadDir(hs.zonePath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	type zoneFile struct {
+		name    string
+		data    []byte
+		modTime time.Time
+	}
+
+	var files []zoneFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: %s", entry.Name(), err.Error()),
+			})
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join(hs.zonePath, entry.Name()))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: %s", entry.Name(), err.Error()),
+			})
+			return
+		}
+
+		files = append(files, zoneFile{name: entry.Name(), data: data, modTime: info.ModTime()})
+	}
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", `attachment; filename="zones.tar.gz"`)
+
+	gz := gzip.NewWriter(c.Writer)
+	tw := tar.NewWriter(gz)
+
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    f.name,
+			Size:    int64(len(f.data)),
+			Mode:    0644,
+			ModTime: f.modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			break
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			break
+		}
+	}
+
+	tw.Close()
+	gz.Close()
+}
+
+// importZones accepts a .tar.gz produced by exportZones (or any archive
+// with one zone JSON document per entry), validates every entry before
+// touching disk, and only then writes the files and swaps the live
+// handlers — so a bad archive never leaves the zone set half-updated.
+func (hs *httpServer) importZones(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, archiveMaxRequestBody)
+
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid gzip archive: " + err.Error(),
+		})
+		return
+	}
+	defer gz.Close()
+
+	type importedZone struct {
+		name string
+		data []byte
+		zone *zones.Zone
+	}
+
+	var imports []importedZone
+	var totalSize int64
+	var entryCount int
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid tar archive: " + err.Error(),
+			})
+			return
+		}
+
+		// Every entry counts against the caps, including ones we're
+		// about to skip (directories, symlinks, non-.json files) —
+		// tar.Reader still has to read and decompress their declared
+		// payload to reach the next header, so skipping them here
+		// would let an archive of padding entries force unbounded
+		// decompression despite the caps below.
+		entryCount++
+		if entryCount > archiveMaxEntries {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("archive has more than %d entries", archiveMaxEntries),
+			})
+			return
+		}
+		totalSize += header.Size
+		if totalSize > archiveMaxTotalSize {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("archive exceeds cumulative limit of %d bytes", archiveMaxTotalSize),
+			})
+			return
+		}
+		if header.Size > archiveMaxFileSize {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: exceeds per-file limit of %d bytes", header.Name, archiveMaxFileSize),
+			})
+			return
+		}
+
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, ".json") {
+			continue
+		}
+
+		zoneName := strings.TrimSuffix(filepath.Base(header.Name), ".json")
+
+		data, err := io.ReadAll(io.LimitReader(tr, archiveMaxFileSize))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: %s", zoneName, err.Error()),
+			})
+			return
+		}
+
+		var objmap map[string]interface{}
+		if err := json.Unmarshal(data, &objmap); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: %s", zoneName, err.Error()),
+			})
+			return
+		}
+
+		zone, err := validateZoneJson(zoneName, objmap)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: %s", zoneName, err.Error()),
+			})
+			return
+		}
+
+		imports = append(imports, importedZone{name: zoneName, data: data, zone: zone})
+	}
+
+	if len(imports) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "archive contained no zone files",
+		})
+		return
+	}
+
+	// Everything parsed and validated; now install all-or-nothing. Every
+	// file is staged to its ".tmp" sibling first, with no effect on the
+	// live files. Only once every file is staged do we back up each
+	// target's current bytes and rename the staged files into place; if
+	// a rename partway through fails, every already-renamed file is
+	// restored from its backup and no handler is swapped, so disk and
+	// the in-memory MuxManager can never end up disagreeing.
+	for i, imp := range imports {
+		if err := writeZoneFileTemp(hs.zoneFilePath(imp.name), imp.data); err != nil {
+			for _, staged := range imports[:i] {
+				os.Remove(hs.zoneFilePath(staged.name) + ".tmp")
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: %s", imp.name, err.Error()),
+			})
+			return
+		}
+	}
+
+	type backup struct {
+		path    string
+		existed bool
+		data    []byte
+	}
+	backups := make([]backup, len(imports))
+	for i, imp := range imports {
+		path := hs.zoneFilePath(imp.name)
+		data, err := os.ReadFile(path)
+		backups[i] = backup{path: path, existed: err == nil, data: data}
+	}
+
+	committed := 0
+	for _, imp := range imports {
+		if err := commitZoneFileTemp(hs.zoneFilePath(imp.name)); err != nil {
+			for _, b := range backups[:committed] {
+				if b.existed {
+					os.WriteFile(b.path, b.data, 0644)
+				} else {
+					os.Remove(b.path)
+				}
+			}
+			for _, pending := range imports[committed:] {
+				os.Remove(hs.zoneFilePath(pending.name) + ".tmp")
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: %s", imp.name, err.Error()),
+			})
+			return
+		}
+		committed++
+	}
+
+	for _, imp := range imports {
+		hs.zones.AddHandler(imp.name, imp.zone)
+	}
+
+	names := make([]string, 0, len(imports))
+	for _, imp := range imports {
+		names = append(names, imp.name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  names,
+	})
+}
+
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteZoneFileAtomicLeavesNoTempOnSuccess(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "example.com.json")
+
+	if err := writeZoneFileAtomic(path, []byte(`{"serial":1}`)); err != nil {
+		t.Fatalf("writeZoneFileAtomic: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading committed file: %v", err)
+	}
+	if string(data) != `{"serial":1}` {
+		t.Errorf("unexpected file contents: %s", data)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected .tmp file to be gone after commit, stat err = %v", err)
+	}
+}
+
+func TestWriteZoneFileAtomicNeverPartiallyOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "example.com.json")
+
+	if err := os.WriteFile(path, []byte(`{"serial":1}`), 0644); err != nil {
+		t.Fatalf("seeding original file: %v", err)
+	}
+
+	// Stage without committing, mirroring the first phase of
+	// importZones: the original file must be untouched until the
+	// rename actually happens.
+	if err := writeZoneFileTemp(path, []byte(`{"serial":2}`)); err != nil {
+		t.Fatalf("writeZoneFileTemp: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading original file: %v", err)
+	}
+	if string(data) != `{"serial":1}` {
+		t.Errorf("original file was modified before commit: %s", data)
+	}
+
+	if err := commitZoneFileTemp(path); err != nil {
+		t.Fatalf("commitZoneFileTemp: %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading committed file: %v", err)
+	}
+	if string(data) != `{"serial":2}` {
+		t.Errorf("commit did not apply staged contents: %s", data)
+	}
+}
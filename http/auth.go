@@ -0,0 +1,165 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/abh/geodns/v3/appconfig"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims carries the scoped permissions encoded in an admin API token:
+// which zones it may touch and which operations it is allowed to perform
+// on them, on top of the standard registered claims (exp, nbf, iss).
+type Claims struct {
+	jwt.RegisteredClaims
+	Zones  []string `json:"zones"`
+	Scopes []string `json:"scopes"`
+}
+
+const (
+	scopeZoneRead  = "zone:read"
+	scopeZoneWrite = "zone:write"
+	scopeAdmin     = "admin:all"
+)
+
+// checkToken verifies a JWT bearer token (HS256 or RS256, selected per
+// request via the token's "kid" header so keys can be rotated without
+// downtime) and stashes the parsed claims for route handlers to consult.
+func (hs *httpServer) checkToken(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Authorization header is missing",
+		})
+		return
+	}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, jwtKeyFunc,
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithIssuer(appconfig.Config.HTTP.JWT.Issuer))
+	if err != nil || !token.Valid {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Unauthorized (401)",
+		})
+		return
+	}
+
+	c.Set("claims", claims)
+	c.Next()
+}
+
+// jwtKeyFunc resolves the signing key for a token from its "kid" header
+// against the configured key set, so HS256 and RS256 keys can be rotated
+// independently without invalidating tokens signed with other keys.
+//
+// Each kid is provisioned for exactly one algorithm (key.Algorithm), and
+// the token's own "alg" header must match it. The header is attacker
+// controlled, so it must never be the thing that decides whether we
+// verify with key.Secret (HS256) or key.PublicKey (RS256) — otherwise a
+// kid provisioned only for RS256 (PublicKey set, Secret left empty)
+// could be "verified" as HS256 with the trivially-known key "".
+func jwtKeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := appconfig.Config.HTTP.JWT.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("jwt: unknown key id %q", kid)
+	}
+
+	alg := token.Method.Alg()
+	if alg != key.Algorithm {
+		return nil, fmt.Errorf("jwt: key id %q is not provisioned for algorithm %q", kid, alg)
+	}
+
+	switch alg {
+	case "HS256":
+		return []byte(key.Secret), nil
+	case "RS256":
+		return jwt.ParseRSAPublicKeyFromPEM([]byte(key.PublicKey))
+	default:
+		return nil, fmt.Errorf("jwt: unsupported signing method %q", alg)
+	}
+}
+
+// claimsFromContext returns the claims stashed by checkToken. Uses Get,
+// not MustGet, since MustGet panics when "claims" hasn't been set —
+// which must stay a safe false rather than a 500 if this is ever called
+// by a route that isn't behind the authorized group.
+func claimsFromContext(c *gin.Context) (*Claims, bool) {
+	value, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*Claims)
+	return claims, ok
+}
+
+// hasScope reports whether the request's claims grant scope, either
+// directly or via the admin:all catch-all scope.
+func hasScope(c *gin.Context, scope string) bool {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return false
+	}
+	return claimsHaveScope(claims, scope)
+}
+
+func claimsHaveScope(claims *Claims, scope string) bool {
+	for _, s := range claims.Scopes {
+		if s == scope || s == scopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// canAccessZone reports whether the request's claims grant access to
+// zoneName, honoring a "*.suffix" wildcard entry alongside exact names.
+func canAccessZone(c *gin.Context, zoneName string) bool {
+	claims, ok := claimsFromContext(c)
+	if !ok {
+		return false
+	}
+	return claimsCanAccessZone(claims, zoneName)
+}
+
+func claimsCanAccessZone(claims *Claims, zoneName string) bool {
+	for _, z := range claims.Zones {
+		if z == zoneName || z == "*" {
+			return true
+		}
+		if strings.HasPrefix(z, "*.") && strings.HasSuffix(zoneName, z[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireZoneScope guards a route with both a scope check and, when the
+// route has a :zone parameter, a zone-access check, so a token can be
+// limited to reading one zone, writing a subset, or admin-all.
+func requireZoneScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasScope(c, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Forbidden",
+			})
+			return
+		}
+		if zoneName := c.Param("zone"); zoneName != "" && !canAccessZone(c, zoneName) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Forbidden",
+			})
+			return
+		}
+		c.Next()
+	}
+}
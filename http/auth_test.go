@@ -0,0 +1,54 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/abh/geodns/v3/appconfig"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJwtKeyFuncRejectsAlgorithmConfusion(t *testing.T) {
+	appconfig.Config.HTTP.JWT.Keys = map[string]appconfig.JWTKey{
+		"rsa-key-1": {
+			Algorithm: "RS256",
+			PublicKey: "-----BEGIN PUBLIC KEY-----\nnot-a-real-key\n-----END PUBLIC KEY-----",
+		},
+	}
+
+	// An attacker-forged token claiming HS256 for a kid that was only
+	// ever provisioned for RS256 must be rejected before any key
+	// material is handed back, regardless of what the header asks for.
+	token := jwt.New(jwt.SigningMethodHS256)
+	token.Header["kid"] = "rsa-key-1"
+
+	if _, err := jwtKeyFunc(token); err == nil {
+		t.Fatalf("expected jwtKeyFunc to reject an HS256 token for an RS256-only key, got nil error")
+	}
+}
+
+func TestJwtKeyFuncUnknownKid(t *testing.T) {
+	appconfig.Config.HTTP.JWT.Keys = map[string]appconfig.JWTKey{}
+
+	token := jwt.New(jwt.SigningMethodHS256)
+	token.Header["kid"] = "missing"
+
+	if _, err := jwtKeyFunc(token); err == nil {
+		t.Fatalf("expected jwtKeyFunc to reject an unknown kid, got nil error")
+	}
+}
+
+func TestClaimsCanAccessZone(t *testing.T) {
+	claims := &Claims{Zones: []string{"example.com", "*.foo.net"}}
+
+	cases := map[string]bool{
+		"example.com": true,
+		"bar.foo.net": true,
+		"other.com":   false,
+		"foo.net":     false,
+	}
+	for zone, want := range cases {
+		if got := claimsCanAccessZone(claims, zone); got != want {
+			t.Errorf("claimsCanAccessZone(%q) = %v, want %v", zone, got, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abh/geodns/v3/appconfig"
+	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
+)
+
+const dohMaxBodySize = 65535
+
+// dohQuery implements RFC 8484 DNS-over-HTTPS, dispatching through the
+// same zones.MuxManager used by the UDP/TCP server so DoH answers go
+// through identical geo resolution (including EDNS Client Subnet).
+func (hs *httpServer) dohQuery(c *gin.Context) {
+	var wire []byte
+
+	switch c.Request.Method {
+	case http.MethodGet:
+		encoded := c.Query("dns")
+		if len(encoded) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "missing dns query parameter",
+			})
+			return
+		}
+		if len(encoded) > base64.RawURLEncoding.EncodedLen(dohMaxBodySize) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"success": false,
+				"error":   "dns query parameter too large",
+			})
+			return
+		}
+		decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid base64url dns parameter",
+			})
+			return
+		}
+		wire = decoded
+
+	case http.MethodPost:
+		if ct := c.ContentType(); ct != "application/dns-message" {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{
+				"success": false,
+				"error":   "Content-Type must be application/dns-message",
+			})
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, dohMaxBodySize))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		wire = body
+
+	default:
+		c.AbortWithStatus(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := new(dns.Msg)
+	if err := query.Unpack(wire); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "malformed dns message",
+		})
+		return
+	}
+
+	hs.addSyntheticECS(query, c)
+
+	rw := newDohResponseWriter(c.Request.RemoteAddr)
+	hs.zones.ServeDNS(rw, query)
+
+	if rw.msg == nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	reply, err := rw.msg.Pack()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", dohCacheControl(rw.msg))
+	c.Data(http.StatusOK, "application/dns-message", reply)
+}
+
+// addSyntheticECS adds an EDNS Client Subnet option derived from
+// X-Forwarded-For to queries arriving through a trusted proxy, so DoH
+// clients behind a CDN or load balancer still get geo-accurate answers.
+func (hs *httpServer) addSyntheticECS(query *dns.Msg, c *gin.Context) {
+	if query.IsEdns0() != nil {
+		// the client already set its own EDNS0 options (possibly
+		// including Client Subnet); don't override it.
+		return
+	}
+
+	if !slices.Contains(appconfig.Config.HTTP.DoH.TrustedProxies, clientIP(c.Request.RemoteAddr)) {
+		return
+	}
+
+	forwardedFor := c.GetHeader("X-Forwarded-For")
+	if len(forwardedFor) == 0 {
+		return
+	}
+	addrString := strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+	ip := net.ParseIP(addrString)
+	if ip == nil {
+		return
+	}
+
+	opt := new(dns.OPT)
+	opt.Hdr.Name = "."
+	opt.Hdr.Rrtype = dns.TypeOPT
+
+	subnet := new(dns.EDNS0_SUBNET)
+	subnet.Code = dns.EDNS0SUBNET
+	subnet.Family = 1
+	subnet.SourceNetmask = 32
+	subnet.SourceScope = 0
+	if v4 := ip.To4(); v4 != nil {
+		subnet.Address = v4
+	} else {
+		subnet.Family = 2
+		subnet.SourceNetmask = 128
+		subnet.Address = ip
+	}
+	opt.Option = append(opt.Option, subnet)
+	query.Extra = append(query.Extra, opt)
+}
+
+func clientIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// dohCacheControl derives a max-age from the lowest TTL in the reply, as
+// recommended by RFC 8484, falling back to a short default for answers
+// with no records to take a TTL from.
+func dohCacheControl(msg *dns.Msg) string {
+	min := uint32(0)
+	found := false
+	for _, rr := range slices.Concat(msg.Answer, msg.Ns, msg.Extra) {
+		if rr.Header().Rrtype == dns.TypeOPT {
+			// the OPT pseudo-record's Ttl field carries EDNS flags, not a TTL
+			continue
+		}
+		ttl := rr.Header().Ttl
+		if !found || ttl < min {
+			min = ttl
+			found = true
+		}
+	}
+	if !found {
+		min = 60
+	}
+	return "max-age=" + strconv.FormatUint(uint64(min), 10)
+}
+
+// dohResponseWriter adapts dns.ResponseWriter to capture the reply in
+// memory instead of writing it to a network connection, so ServeDNS can
+// be reused unmodified for the HTTP transport.
+type dohResponseWriter struct {
+	remoteAddr net.Addr
+	msg        *dns.Msg
+}
+
+func newDohResponseWriter(remoteAddr string) *dohResponseWriter {
+	host, portString, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	port, _ := strconv.Atoi(portString)
+	return &dohResponseWriter{remoteAddr: &net.TCPAddr{IP: net.ParseIP(host), Port: port}}
+}
+
+func (w *dohResponseWriter) LocalAddr() net.Addr         { return &net.TCPAddr{} }
+func (w *dohResponseWriter) RemoteAddr() net.Addr        { return w.remoteAddr }
+func (w *dohResponseWriter) WriteMsg(m *dns.Msg) error   { w.msg = m; return nil }
+func (w *dohResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *dohResponseWriter) Close() error                { return nil }
+func (w *dohResponseWriter) TsigStatus() error           { return nil }
+func (w *dohResponseWriter) TsigTimersOnly(bool)         {}
+func (w *dohResponseWriter) Hijack()                     {}
@@ -9,13 +9,12 @@ import (
 	"net/http"
 	"os"
 	"slices"
-	"strings"
 	"time"
 
-	"github.com/abh/geodns/v3/appconfig"
 	"github.com/abh/geodns/v3/monitor"
 	"github.com/abh/geodns/v3/zones"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -27,42 +26,37 @@ type httpServer struct {
 }
 
 func NewHTTPServer(mm *zones.MuxManager, serverInfo *monitor.ServerInfo, path string) *httpServer {
-	application := gin.Default()
+	application := gin.New()
 	hs := &httpServer{
 		zones:       mm,
 		application: application,
 		serverInfo:  serverInfo,
 		zonePath:    path,
 	}
+
+	hs.application.Use(gin.Recovery(), requestID(), requestLogger(), corsMiddleware(), securityHeaders())
+	hs.application.NoRoute(corsPreflight)
+
+	hs.application.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	hs.application.GET("/dns-query", hs.dohQuery)
+	hs.application.POST("/dns-query", hs.dohQuery)
+
 	authorized := hs.application.Group("/", hs.checkToken)
 
-	authorized.GET("/zone", hs.getZones)
-	authorized.GET("/zone/:zone", hs.getZone)
-	authorized.POST("/zone/:zone", hs.addZone)
+	authorized.GET("/zone", requireZoneScope(scopeZoneRead), hs.getZones)
+	authorized.GET("/zone/:zone", requireZoneScope(scopeZoneRead), hs.getZone)
+	authorized.POST("/zone/:zone", requireZoneScope(scopeZoneWrite), hs.addZone)
+	authorized.PATCH("/zone/:zone", requireZoneScope(scopeZoneWrite), hs.patchZone)
+	authorized.DELETE("/zone/:zone", requireZoneScope(scopeZoneWrite), hs.deleteZone)
+	authorized.POST("/zone/:zone/reload", requireZoneScope(scopeZoneWrite), hs.reloadZone)
+	authorized.POST("/reload", requireZoneScope(scopeAdmin), hs.reloadAll)
+	authorized.GET("/zones/export", requireZoneScope(scopeAdmin), hs.exportZones)
+	authorized.POST("/zones/import", requireZoneScope(scopeAdmin), hs.importZones)
 
 	return hs
 }
 
-func (hs *httpServer) checkToken(c *gin.Context) {
-	authHeader := c.GetHeader("Authorization")
-	if !strings.HasPrefix(authHeader, "Bearer") {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "Authorization header is missing",
-		})
-		return
-	}
-	token := strings.Replace(authHeader, "Bearer ", "", -1)
-	if token != appconfig.Config.HTTP.Token {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "Unauthorized (401)",
-		})
-		return
-	}
-	c.Next()
-}
-
 func (hs *httpServer) Run(ctx context.Context, listen string) error {
 	log.Println("Starting HTTP interface on", listen)
 
@@ -100,7 +94,7 @@ func (hs *httpServer) getZones(c *gin.Context) {
 	zones := hs.zones.Zones()
 	keys := slices.Collect(maps.Keys(zones))
 	keys = slices.DeleteFunc(keys, func(key string) bool {
-		return key == "pgeodns"
+		return key == "pgeodns" || !canAccessZone(c, key)
 	})
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -126,10 +120,7 @@ func (hs *httpServer) getZone(c *gin.Context) {
 
 func (hs *httpServer) addZone(c *gin.Context) {
 	zoneName := c.Param("zone")
-	zone := hs.zones.Zones()[zoneName]
-	if zone == nil {
-		zone = zones.NewZone(zoneName)
-	}
+
 	var objmap map[string]interface{}
 	if err := c.ShouldBindJSON(&objmap); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -139,7 +130,13 @@ func (hs *httpServer) addZone(c *gin.Context) {
 		return
 	}
 
-	zone.ReadZoneJson(objmap)
+	if _, err := validateZoneJson(zoneName, objmap); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
 
 	data, err := json.Marshal(objmap)
 	if err != nil {
@@ -150,7 +147,74 @@ func (hs *httpServer) addZone(c *gin.Context) {
 		return
 	}
 
-	err = os.WriteFile(hs.zonePath+zoneName+".json", data, 0644)
+	if err := writeZoneFileAtomic(hs.zoneFilePath(zoneName), data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	diff, err := hs.applyZoneFile(zoneName, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  "Zone created successfully",
+		"diff":    diff,
+	})
+}
+
+// patchZone merges a partial JSON document (e.g. a single label or a single
+// record within a label) into the existing zone instead of requiring the
+// caller to re-upload the whole document.
+func (hs *httpServer) patchZone(c *gin.Context) {
+	zoneName := c.Param("zone")
+	existing := hs.zones.Zones()[zoneName]
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Zone not found",
+		})
+		return
+	}
+
+	current, err := hs.readZoneFile(zoneName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	var patch map[string]interface{}
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	mergeZoneJson(current, patch)
+
+	zone, err := validateZoneJson(zoneName, current)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	data, err := json.Marshal(current)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -159,10 +223,160 @@ func (hs *httpServer) addZone(c *gin.Context) {
 		return
 	}
 
+	if err := writeZoneFileAtomic(hs.zoneFilePath(zoneName), data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	hs.zones.AddHandler(zoneName, zone)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"result":  "Zone created successfully",
+		"result":  "Zone updated successfully",
+	})
+}
+
+func (hs *httpServer) deleteZone(c *gin.Context) {
+	zoneName := c.Param("zone")
+	if hs.zones.Zones()[zoneName] == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Zone not found",
+		})
+		return
+	}
+
+	if err := os.Remove(hs.zoneFilePath(zoneName)); err != nil && !os.IsNotExist(err) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	hs.zones.RemoveHandler(zoneName)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  "Zone deleted successfully",
 	})
 }
+
+// zoneFilePath returns the on-disk path for a zone's JSON document.
+func (hs *httpServer) zoneFilePath(zoneName string) string {
+	return hs.zonePath + zoneName + ".json"
+}
+
+// readZoneFile loads and decodes the zone document currently on disk so a
+// PATCH can be merged against it.
+func (hs *httpServer) readZoneFile(zoneName string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(hs.zoneFilePath(zoneName))
+	if err != nil {
+		return nil, err
+	}
+	var objmap map[string]interface{}
+	if err := json.Unmarshal(data, &objmap); err != nil {
+		return nil, err
+	}
+	return objmap, nil
+}
+
+// mergeZoneJson merges patch's top-level keys into dst, recursing into
+// "labels" and, within each label, into its own nested maps (e.g.
+// "records"), so a PATCH can touch a single record of a single type
+// within a label without the caller re-sending the label's other record
+// types or its other labels.
+func mergeZoneJson(dst, patch map[string]interface{}) {
+	for key, value := range patch {
+		if key != "labels" {
+			dst[key] = value
+			continue
+		}
+
+		patchLabels, ok := value.(map[string]interface{})
+		if !ok {
+			dst[key] = value
+			continue
+		}
+
+		dstLabels, ok := dst["labels"].(map[string]interface{})
+		if !ok {
+			dstLabels = map[string]interface{}{}
+		}
+		for label, labelValue := range patchLabels {
+			if labelValue == nil {
+				delete(dstLabels, label)
+				continue
+			}
+			if existing, ok := dstLabels[label]; ok {
+				dstLabels[label] = mergeJsonValue(existing, labelValue)
+			} else {
+				dstLabels[label] = labelValue
+			}
+		}
+		dst["labels"] = dstLabels
+	}
+}
+
+// mergeJsonValue recursively merges patch into dst when both decode to
+// JSON objects (so e.g. a label's "records" map merges type-by-type
+// instead of one type's update wiping out the others), and otherwise
+// treats patch as a full replacement of dst, matching encoding/json's
+// map[string]interface{} representation of nested objects vs. scalars
+// and arrays.
+func mergeJsonValue(dst, patch interface{}) interface{} {
+	dstMap, dstOk := dst.(map[string]interface{})
+	patchMap, patchOk := patch.(map[string]interface{})
+	if !dstOk || !patchOk {
+		return patch
+	}
+
+	for key, value := range patchMap {
+		if value == nil {
+			delete(dstMap, key)
+			continue
+		}
+		if existing, ok := dstMap[key]; ok {
+			dstMap[key] = mergeJsonValue(existing, value)
+		} else {
+			dstMap[key] = value
+		}
+	}
+	return dstMap
+}
+
+// validateZoneJson dry-runs ReadZoneJson against a scratch zone so malformed
+// documents are rejected before they're written to disk or swapped into the
+// live MuxManager.
+func validateZoneJson(zoneName string, objmap map[string]interface{}) (*zones.Zone, error) {
+	zone := zones.NewZone(zoneName)
+	if err := zone.ReadZoneJson(objmap); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// writeZoneFileAtomic writes data to a temporary file alongside path and
+// renames it into place, so a crashed request can never leave a
+// half-written zone file that then fails to parse on reload.
+func writeZoneFileAtomic(path string, data []byte) error {
+	if err := writeZoneFileTemp(path, data); err != nil {
+		return err
+	}
+	return commitZoneFileTemp(path)
+}
+
+// writeZoneFileTemp writes data to path's ".tmp" sibling without
+// installing it, so a multi-file operation (e.g. importZones) can stage
+// every file before committing any of them.
+func writeZoneFileTemp(path string, data []byte) error {
+	return os.WriteFile(path+".tmp", data, 0644)
+}
+
+// commitZoneFileTemp renames path's staged ".tmp" sibling into place.
+func commitZoneFileTemp(path string) error {
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeZoneJsonPreservesOtherRecordTypes(t *testing.T) {
+	dst := map[string]interface{}{
+		"labels": map[string]interface{}{
+			"www": map[string]interface{}{
+				"ttl": float64(300),
+				"records": map[string]interface{}{
+					"A":    []interface{}{map[string]interface{}{"ip": "1.2.3.4"}},
+					"AAAA": []interface{}{map[string]interface{}{"ip": "::1"}},
+				},
+			},
+			"mail": map[string]interface{}{
+				"records": map[string]interface{}{
+					"MX": []interface{}{map[string]interface{}{"ip": "mx.example.com"}},
+				},
+			},
+		},
+	}
+
+	patch := map[string]interface{}{
+		"labels": map[string]interface{}{
+			"www": map[string]interface{}{
+				"records": map[string]interface{}{
+					"A": []interface{}{map[string]interface{}{"ip": "5.6.7.8"}},
+				},
+			},
+		},
+	}
+
+	mergeZoneJson(dst, patch)
+
+	labels := dst["labels"].(map[string]interface{})
+
+	www := labels["www"].(map[string]interface{})
+	records := www["records"].(map[string]interface{})
+
+	if got := records["A"]; !reflect.DeepEqual(got, []interface{}{map[string]interface{}{"ip": "5.6.7.8"}}) {
+		t.Errorf("A record not replaced, got %v", got)
+	}
+	if got := records["AAAA"]; !reflect.DeepEqual(got, []interface{}{map[string]interface{}{"ip": "::1"}}) {
+		t.Errorf("AAAA record was wiped out by unrelated patch to A, got %v", got)
+	}
+	if got := www["ttl"]; got != float64(300) {
+		t.Errorf("unrelated label field ttl was lost, got %v", got)
+	}
+
+	if _, ok := labels["mail"]; !ok {
+		t.Errorf("unrelated label mail was removed by patch")
+	}
+}
+
+func TestMergeZoneJsonDeletesLabelOnNil(t *testing.T) {
+	dst := map[string]interface{}{
+		"labels": map[string]interface{}{
+			"www": map[string]interface{}{"ttl": float64(300)},
+		},
+	}
+	patch := map[string]interface{}{
+		"labels": map[string]interface{}{
+			"www": nil,
+		},
+	}
+
+	mergeZoneJson(dst, patch)
+
+	labels := dst["labels"].(map[string]interface{})
+	if _, ok := labels["www"]; ok {
+		t.Errorf("expected label www to be removed")
+	}
+}
@@ -0,0 +1,18 @@
+package http
+
+import "testing"
+
+func TestMethodLabelNormalizesUnknownVerbs(t *testing.T) {
+	cases := map[string]string{
+		"GET":         "GET",
+		"POST":        "POST",
+		"FROB":        "OTHER",
+		"GET /../../": "OTHER",
+		"":            "OTHER",
+	}
+	for in, want := range cases {
+		if got := methodLabel(in); got != want {
+			t.Errorf("methodLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
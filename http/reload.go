@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/abh/geodns/v3/zones"
+	"github.com/gin-gonic/gin"
+)
+
+// zoneDiff summarizes what changed between the zone currently registered
+// in the MuxManager and the document just read from disk, so callers can
+// see the effect of a reload without diffing the JSON themselves.
+type zoneDiff struct {
+	LabelsAdded   []string `json:"labels_added"`
+	LabelsRemoved []string `json:"labels_removed"`
+	LabelsChanged []string `json:"labels_changed"`
+	OldSerial     uint32   `json:"old_serial,omitempty"`
+	NewSerial     uint32   `json:"new_serial"`
+}
+
+// applyZoneFile reads zoneName's JSON document from disk, validates it,
+// and (unless dryRun) swaps it into the live MuxManager, returning a
+// summary of what changed. It is the single apply routine shared by
+// addZone and the reload endpoints, so a git-ops file edit and a POST
+// /zone/:zone upload go through the same validation and diffing path.
+func (hs *httpServer) applyZoneFile(zoneName string, dryRun bool) (*zoneDiff, error) {
+	data, err := os.ReadFile(hs.zoneFilePath(zoneName))
+	if err != nil {
+		return nil, err
+	}
+
+	var objmap map[string]interface{}
+	if err := json.Unmarshal(data, &objmap); err != nil {
+		return nil, err
+	}
+
+	newZone, err := validateZoneJson(zoneName, objmap)
+	if err != nil {
+		return nil, err
+	}
+
+	oldZone := hs.zones.Zones()[zoneName]
+	diff := diffZones(oldZone, newZone)
+
+	if !dryRun {
+		hs.zones.AddHandler(zoneName, newZone)
+	}
+
+	return diff, nil
+}
+
+// diffZones compares the label sets of oldZone (nil for a brand-new zone)
+// and newZone, and reports their serial numbers.
+func diffZones(oldZone, newZone *zones.Zone) *zoneDiff {
+	diff := &zoneDiff{NewSerial: newZone.Serial}
+	if oldZone == nil {
+		diff.LabelsAdded = slices.Sorted(maps.Keys(newZone.Labels))
+		return diff
+	}
+
+	diff.OldSerial = oldZone.Serial
+
+	for label := range newZone.Labels {
+		if _, ok := oldZone.Labels[label]; !ok {
+			diff.LabelsAdded = append(diff.LabelsAdded, label)
+		}
+	}
+	for label := range oldZone.Labels {
+		if _, ok := newZone.Labels[label]; !ok {
+			diff.LabelsRemoved = append(diff.LabelsRemoved, label)
+		}
+	}
+	for label, newRecord := range newZone.Labels {
+		if oldRecord, ok := oldZone.Labels[label]; ok && !labelsEqual(oldRecord, newRecord) {
+			diff.LabelsChanged = append(diff.LabelsChanged, label)
+		}
+	}
+
+	slices.Sort(diff.LabelsAdded)
+	slices.Sort(diff.LabelsRemoved)
+	slices.Sort(diff.LabelsChanged)
+
+	return diff
+}
+
+func labelsEqual(a, b *zones.Label) bool {
+	aj, aErr := json.Marshal(a)
+	bj, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	return string(aj) == string(bj)
+}
+
+// reloadZone re-reads a single zone's JSON file from hs.zonePath and
+// re-registers it, for operators who edit zone files out-of-band
+// (git-ops workflows) and want to apply them without a SIGHUP.
+func (hs *httpServer) reloadZone(c *gin.Context) {
+	zoneName := c.Param("zone")
+	dryRun := c.Query("dry_run") == "true"
+
+	diff, err := hs.applyZoneFile(zoneName, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  diff,
+	})
+}
+
+// reloadAll re-applies every zone file found under hs.zonePath,
+// including ones with no handler registered yet, so an operator who
+// drops a brand-new zone file into the directory (the git-ops use case
+// this endpoint exists for) has it picked up by POST /reload without
+// first needing a SIGHUP or a POST /zone/:zone.
+func (hs *httpServer) reloadAll(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	entries, err := os.ReadDir(hs.zonePath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	diffs := map[string]*zoneDiff{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		zoneName := strings.TrimSuffix(entry.Name(), ".json")
+		if zoneName == "pgeodns" {
+			continue
+		}
+
+		diff, err := hs.applyZoneFile(zoneName, dryRun)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   zoneName + ": " + err.Error(),
+			})
+			return
+		}
+		diffs[zoneName] = diff
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  diffs,
+	})
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/abh/geodns/v3/appconfig"
+	"github.com/gin-gonic/gin"
+)
+
+// corsMiddleware applies the configured CORS policy to every request and
+// answers preflight OPTIONS requests directly, so a browser-based zone
+// management UI served from a different origin can call the API.
+func corsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := appconfig.Config.HTTP.CORS
+		origin := c.GetHeader("Origin")
+		if len(origin) > 0 && corsOriginAllowed(cfg.AllowedOrigins, origin) {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+			c.Header("Access-Control-Allow-Methods", strings.Join(corsOrDefault(cfg.AllowedMethods, []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"}), ", "))
+			c.Header("Access-Control-Allow-Headers", strings.Join(corsOrDefault(cfg.AllowedHeaders, []string{"Authorization", "Content-Type"}), ", "))
+		}
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// corsPreflight is the NoRoute handler for requests that don't match any
+// registered route. OPTIONS preflight itself is already handled for
+// every path, matched or not, by corsMiddleware (a global Use()
+// middleware that runs ahead of routing), so by the time a request
+// reaches here it is never an OPTIONS request — this is just a plain
+// JSON 404.
+func corsPreflight(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"success": false,
+		"error":   "Not found",
+	})
+}
+
+func corsOriginAllowed(allowed []string, origin string) bool {
+	return slices.Contains(allowed, "*") || slices.Contains(allowed, origin)
+}
+
+func corsOrDefault(configured, fallback []string) []string {
+	if len(configured) > 0 {
+		return configured
+	}
+	return fallback
+}
+
+// securityHeaders sets a conservative set of response headers for the
+// admin API, so operators don't have to front it with a separate reverse
+// proxy just to get basic hardening.
+func securityHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := appconfig.Config.HTTP.Security
+
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+
+		maxAge := cfg.STSMaxAge
+		if maxAge == 0 {
+			maxAge = 31536000
+		}
+		if cfg.EnforceHTTPS {
+			c.Header("Strict-Transport-Security", fmt.Sprintf("max-age=%d; includeSubDomains", maxAge))
+			if c.Request.Header.Get("X-Forwarded-Proto") == "http" {
+				target := "https://" + c.Request.Host + c.Request.URL.RequestURI()
+				c.Redirect(http.StatusMovedPermanently, target)
+				c.Abort()
+				return
+			}
+		}
+
+		csp := cfg.ContentSecurityPolicy
+		if len(csp) == 0 {
+			csp = "default-src 'none'; frame-ancestors 'none'"
+		}
+		c.Header("Content-Security-Policy", csp)
+
+		c.Next()
+	}
+}